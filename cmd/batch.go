@@ -0,0 +1,100 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"syscall/js"
+
+	"github.com/dominant-strategies/progpow-wasm/epochcache"
+)
+
+// verifyProgPoWBatch verifies many WorkObjectHeaders in a single WASM
+// boundary crossing. Headers are grouped by epoch so the light
+// cache/cDAG (see epochCache) is generated at most once per epoch per
+// batch, and each group is then fanned out across a worker pool.
+// Args: headers (array of header objects, same shape as verifyWithExactSealHash)
+// Returns: array of {valid, mixHashValid, powValid, powHash, sealHash, error}
+func verifyProgPoWBatch(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeObject {
+		return map[string]interface{}{"error": "Expected: headers (array of header objects)"}
+	}
+	headers := args[0]
+	n := headers.Length()
+
+	items := make([]js.Value, n)
+	groups := make(map[uint64][]int)
+	for i := 0; i < n; i++ {
+		item := headers.Index(i)
+		items[i] = item
+		epoch, err := extractFlexibleUint64(item, "primeTerminusNumber")
+		if err != nil {
+			epoch = 0
+		}
+		epoch /= C_epochLength
+		groups[epoch] = append(groups[epoch], i)
+	}
+
+	results := make([]interface{}, n)
+	workers := runtime.NumCPU()
+	if workers < 1 {
+		workers = 1
+	}
+
+	for epoch, indices := range groups {
+		// Prime this epoch's cache once up front so the worker pool below
+		// never races to regenerate it.
+		if _, err := epochCache.Get(epoch, func() (*epochcache.Entry, error) {
+			return generateEpochEntry(epoch)
+		}); err != nil {
+			msg := fmt.Sprintf("Failed to generate epoch %d cache: %v", epoch, err)
+			for _, idx := range indices {
+				results[idx] = map[string]interface{}{"error": msg}
+			}
+			continue
+		}
+
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for idx := range jobs {
+					results[idx] = verifyBatchItem(this, items[idx])
+				}
+			}()
+		}
+		for _, idx := range indices {
+			jobs <- idx
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	return results
+}
+
+// verifyBatchItem verifies a single header via the same exact-seal-hash path
+// verifyWithExactSealHash uses, trimmed down to the fields a batch caller
+// needs.
+func verifyBatchItem(this js.Value, header js.Value) map[string]interface{} {
+	result := verifyWithExactSealHash(this, []js.Value{header})
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{"error": "internal error: unexpected result type"}
+	}
+	if errVal, hasError := resultMap["error"]; hasError {
+		return map[string]interface{}{"error": errVal}
+	}
+	return map[string]interface{}{
+		"valid":        resultMap["valid"],
+		"mixHashValid": resultMap["mixHashValid"],
+		"powValid":     resultMap["powValid"],
+		"powHash":      resultMap["powHash"],
+		"sealHash":     resultMap["sealHash"],
+	}
+}
@@ -0,0 +1,85 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/dominant-strategies/progpow-wasm/epochcache"
+)
+
+// progpowCacheConfigure adjusts the epoch cache and optionally kicks off
+// background generation for a set of epochs.
+// Args: options ({maxEpochs?: number, preload?: number[]})
+// Returns: the cache stats after applying the options.
+func progpowCacheConfigure(this js.Value, args []js.Value) interface{} {
+	if len(args) >= 1 && args[0].Type() != js.TypeUndefined && args[0].Type() != js.TypeNull {
+		opts := args[0]
+		if maxEpochs := opts.Get("maxEpochs"); maxEpochs.Type() != js.TypeUndefined {
+			epochCache.SetMaxEpochs(maxEpochs.Int())
+		}
+		if preload := opts.Get("preload"); preload.Type() == js.TypeObject {
+			length := preload.Length()
+			for i := 0; i < length; i++ {
+				epoch := uint64(preload.Index(i).Float())
+				go func(epoch uint64) {
+					epochCache.Get(epoch, func() (*epochcache.Entry, error) {
+						return generateEpochEntry(epoch)
+					})
+				}(epoch)
+			}
+		}
+	}
+	return progpowCacheStats(this, nil)
+}
+
+// progpowPrewarmEpoch generates (and caches) the light cache/cDAG for an
+// epoch in the background, returning a Promise that resolves once it's
+// ready. Callers can await this before issuing a burst of verifies against
+// that epoch to avoid paying generation cost on the first one.
+// Args: epoch (number)
+// Returns: Promise<{epoch, datasetSize}>
+func progpowPrewarmEpoch(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return map[string]interface{}{"error": "Expected: epoch (number)"}
+	}
+	epoch := uint64(args[0].Float())
+
+	var handler js.Func
+	handler = js.FuncOf(func(this js.Value, promiseArgs []js.Value) interface{} {
+		resolve := promiseArgs[0]
+		reject := promiseArgs[1]
+		go func() {
+			defer handler.Release()
+			entry, err := epochCache.Get(epoch, func() (*epochcache.Entry, error) {
+				return generateEpochEntry(epoch)
+			})
+			if err != nil {
+				reject.Invoke(js.ValueOf(fmt.Sprintf("Failed to generate epoch %d cache: %v", epoch, err)))
+				return
+			}
+			resolve.Invoke(map[string]interface{}{
+				"epoch":       epoch,
+				"datasetSize": entry.DatasetSize,
+			})
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(handler)
+}
+
+// progpowCacheStats reports which epochs are currently cached.
+// Returns: {maxEpochs: number, epochs: number[]}
+func progpowCacheStats(this js.Value, args []js.Value) interface{} {
+	stats := epochCache.Stats()
+	epochs := make([]interface{}, len(stats.Epochs))
+	for i, e := range stats.Epochs {
+		epochs[i] = e
+	}
+	return map[string]interface{}{
+		"maxEpochs": stats.MaxEpochs,
+		"epochs":    epochs,
+	}
+}
@@ -0,0 +1,34 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import "github.com/dominant-strategies/progpow-wasm/hexutil"
+
+// fieldError builds the structured {field, reason} payload returned to
+// JavaScript when a hex field fails to decode, so callers get an actionable
+// diagnostic instead of a silently empty value.
+func fieldError(field string, err error) map[string]interface{} {
+	return map[string]interface{}{
+		"field":  field,
+		"reason": err.Error(),
+	}
+}
+
+// errorResponse wraps a fieldError in the {"error": ...} envelope every
+// exported WASM function returns on failure.
+func errorResponse(field string, err error) map[string]interface{} {
+	return map[string]interface{}{
+		"error": fieldError(field, err),
+	}
+}
+
+// errorResponseFromErr builds the {"error": ...} envelope from an error that
+// may or may not carry a field name, unwrapping hexutil.FieldError when
+// present so the field/reason split is preserved.
+func errorResponseFromErr(err error) map[string]interface{} {
+	if fe, ok := err.(*hexutil.FieldError); ok {
+		return errorResponse(fe.Field, fe.Reason)
+	}
+	return map[string]interface{}{"error": err.Error()}
+}
@@ -0,0 +1,37 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"math/big"
+	"syscall/js"
+
+	"github.com/dominant-strategies/progpow-wasm/hexutil"
+)
+
+// extractFlexibleUint64 reads a header field that JS may have populated as a
+// 0x-prefixed hex string, a plain decimal string (to dodge JS number
+// precision loss on large values), or a JS number. Missing fields decode to
+// zero.
+func extractFlexibleUint64(headerObj js.Value, field string) (uint64, error) {
+	val := headerObj.Get(field)
+	if val.Type() == js.TypeUndefined {
+		return 0, nil
+	}
+	if val.Type() != js.TypeString {
+		return uint64(val.Float()), nil
+	}
+	str := val.String()
+	if len(str) > 2 && str[:2] == "0x" {
+		v, err := hexutil.DecodeUint64(str)
+		if err != nil {
+			return 0, hexutil.WrapField(field, err)
+		}
+		return v, nil
+	}
+	// Decimal string.
+	dec := new(big.Int)
+	dec.SetString(str, 10)
+	return dec.Uint64(), nil
+}
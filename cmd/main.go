@@ -9,6 +9,8 @@ import (
 	"math/big"
 	"syscall/js"
 
+	"github.com/dominant-strategies/progpow-wasm/epochcache"
+	"github.com/dominant-strategies/progpow-wasm/hexutil"
 	"github.com/dominant-strategies/progpow-wasm/progpow"
 	"github.com/sirupsen/logrus"
 )
@@ -16,9 +18,17 @@ import (
 // C_epochLength is the epoch length for ProgPoW
 const C_epochLength = 388800
 
+// defaultCachedEpochs is how many epochs' light cache/cDAG the epoch cache
+// keeps warm at once.
+const defaultCachedEpochs = 3
+
 // Create a logger for WASM
 var logger = logrus.New()
 
+// epochCache memoizes the light cache and cDAG per epoch so repeated
+// verifications against the same epoch don't regenerate them.
+var epochCache = epochcache.NewManager(defaultCachedEpochs)
+
 func main() {
 	fmt.Println("ProgPoW WASM module initializing...")
 
@@ -35,6 +45,13 @@ func main() {
 	js.Global().Set("computeProgPoW", js.FuncOf(computeProgPoW))
 	js.Global().Set("computeWorkObjectSealHash", js.FuncOf(computeWorkObjectSealHash))
 	js.Global().Set("verifyWithExactSealHash", js.FuncOf(verifyWithExactSealHash))
+	js.Global().Set("progpowCacheConfigure", js.FuncOf(progpowCacheConfigure))
+	js.Global().Set("progpowPrewarmEpoch", js.FuncOf(progpowPrewarmEpoch))
+	js.Global().Set("progpowCacheStats", js.FuncOf(progpowCacheStats))
+	js.Global().Set("verifyProgPoWBatch", js.FuncOf(verifyProgPoWBatch))
+	js.Global().Set("findProgPoWNonce", js.FuncOf(findProgPoWNonce))
+	js.Global().Set("encodeWorkObjectHeader", js.FuncOf(encodeWorkObjectHeader))
+	js.Global().Set("decodeWorkObjectHeader", js.FuncOf(decodeWorkObjectHeader))
 
 	// Signal that the module is ready
 	js.Global().Set("progpowReady", true)
@@ -59,6 +76,16 @@ func progpowInfo(this js.Value, args []js.Value) interface{} {
 	}
 }
 
+// parseNonceArg parses a nonce argument that may be a 0x-prefixed hex string
+// (used to preserve precision for values beyond JS's safe integer range) or
+// a plain JS number.
+func parseNonceArg(arg js.Value) (uint64, error) {
+	if arg.Type() == js.TypeString {
+		return hexutil.DecodeUint64(arg.String())
+	}
+	return uint64(arg.Float()), nil
+}
+
 // computeProgPoW computes the ProgPoW hash for a given header
 // Args: headerHash (hex), nonce (number), blockNumber (number), primeTerminusNumber (number)
 // Returns: {mixHash: hex, powHash: hex}
@@ -69,73 +96,71 @@ func computeProgPoW(this js.Value, args []js.Value) interface{} {
 		}
 	}
 
-	// Parse arguments
-	headerHashHex := args[0].String()
-	
-	// Parse nonce - can be a number or string to handle large values
-	var nonce uint64
-	if args[1].Type() == js.TypeString {
-		nonceStr := args[1].String()
-		if len(nonceStr) > 2 && nonceStr[:2] == "0x" {
-			nonceStr = nonceStr[2:]
-		}
-		nonceBig := new(big.Int)
-		nonceBig.SetString(nonceStr, 16)
-		nonce = nonceBig.Uint64()
-	} else {
-		// For smaller nonces that fit in JavaScript number
-		nonce = uint64(args[1].Float())
+	// Decode header hash
+	headerHash, err := hexutil.DecodeFixed(args[0].String(), 32)
+	if err != nil {
+		return errorResponse("headerHash", err)
 	}
-	
-	_ = uint64(args[2].Float()) // blockNumber - not used, we use primeTerminusNumber for progpowLight  
-	primeTerminusNumber := uint64(args[3].Float())
 
-	// Remove 0x prefix if present
-	if len(headerHashHex) > 2 && headerHashHex[:2] == "0x" {
-		headerHashHex = headerHashHex[2:]
+	// Parse nonce - can be a number or string to handle large values
+	nonce, err := parseNonceArg(args[1])
+	if err != nil {
+		return errorResponse("nonce", err)
 	}
 
-	// Decode header hash
-	headerHash, err := hex.DecodeString(headerHashHex)
-	if err != nil || len(headerHash) != 32 {
-		return map[string]interface{}{
-			"error": "Invalid header hash - must be 32 bytes hex",
-		}
-	}
+	_ = uint64(args[2].Float()) // blockNumber - not used, we use primeTerminusNumber for progpowLight
+	primeTerminusNumber := uint64(args[3].Float())
 
-	// Calculate epoch and cache size
 	// Note: primeTerminusNumber is treated like a block number for epoch calculation
 	epoch := primeTerminusNumber / C_epochLength
-	// CacheSize and DatasetSize expect a block number, not epoch
-	cacheSize := progpow.CacheSize(epoch*C_epochLength + 1)
-	datasetSize := progpow.DatasetSize(epoch*C_epochLength + 1)
-
-	// Generate seed for the epoch
-	// Note: seedHash expects a block number, not epoch number
-	seed := progpow.SeedHash(epoch*C_epochLength + 1)
-
-	// Generate cache
-	cache := make([]uint32, cacheSize/4)
-	progpow.GenerateCache(cache, epoch, seed, logger)
-
-	// Generate cDag
-	cDag := make([]uint32, 16*1024/4) // progpowCacheBytes / 4
-	progpow.GenerateCDag(cDag, cache, epoch, logger)
+	entry, err := epochCache.Get(epoch, func() (*epochcache.Entry, error) {
+		return generateEpochEntry(epoch)
+	})
+	if err != nil {
+		return map[string]interface{}{
+			"error": fmt.Sprintf("Failed to generate epoch %d cache: %v", epoch, err),
+		}
+	}
 
-	
 	// Compute ProgPoW
 	// Note: Go implementation passes primeTerminusNumber as the blockNumber to progpowLight
-	mixHash, powHash := progpow.ProgpowLight(datasetSize, cache, headerHash, nonce, primeTerminusNumber, cDag)
+	mixHash, powHash := progpow.ProgpowLight(entry.DatasetSize, entry.Cache, headerHash, nonce, primeTerminusNumber, entry.CDag)
 
 	return map[string]interface{}{
 		"mixHash":     hex.EncodeToString(mixHash),
 		"powHash":     hex.EncodeToString(powHash),
 		"epoch":       epoch,
-		"cacheSize":   cacheSize,
-		"datasetSize": datasetSize,
+		"cacheSize":   len(entry.Cache) * 4,
+		"datasetSize": entry.DatasetSize,
 	}
 }
 
+// generateEpochEntry generates the light cache and cDAG for an epoch. It
+// backs the GenerateFunc passed to epochCache.Get, so it runs once per
+// epoch while that epoch stays cached, even if multiple calls race to
+// compute the same one.
+func generateEpochEntry(epoch uint64) (*epochcache.Entry, error) {
+	// CacheSize, DatasetSize and SeedHash expect a block number, not an epoch.
+	blockNumber := epoch*C_epochLength + 1
+	cacheSize := progpow.CacheSize(blockNumber)
+	datasetSize := progpow.DatasetSize(blockNumber)
+	seed := progpow.SeedHash(blockNumber)
+
+	cache := make([]uint32, cacheSize/4)
+	progpow.GenerateCache(cache, epoch, seed, logger)
+
+	cDag := make([]uint32, 16*1024/4) // progpowCacheBytes / 4
+	progpow.GenerateCDag(cDag, cache, epoch, logger)
+
+	return &epochcache.Entry{
+		Epoch:       epoch,
+		Cache:       cache,
+		CDag:        cDag,
+		DatasetSize: datasetSize,
+		Seed:        seed,
+	}, nil
+}
+
 // verifyProgPoW performs FULL verification of a ProgPoW hash
 // This recomputes the mixHash and powHash from scratch and verifies both:
 // 1. The provided mixHash matches the computed one
@@ -151,60 +176,36 @@ func verifyProgPoW(this js.Value, args []js.Value) interface{} {
 
 	// Parse arguments
 	headerHashHex := args[0].String()
-	
+	if _, err := hexutil.DecodeFixed(headerHashHex, 32); err != nil {
+		return errorResponse("headerHash", err)
+	}
+
 	// Parse nonce - can be a number or string to handle large values
-	var nonce uint64
-	if args[1].Type() == js.TypeString {
-		nonceStr := args[1].String()
-		if len(nonceStr) > 2 && nonceStr[:2] == "0x" {
-			nonceStr = nonceStr[2:]
-		}
-		nonceBig := new(big.Int)
-		nonceBig.SetString(nonceStr, 16)
-		nonce = nonceBig.Uint64()
-	} else {
-		// For smaller nonces that fit in JavaScript number
-		nonce = uint64(args[1].Float())
+	nonce, err := parseNonceArg(args[1])
+	if err != nil {
+		return errorResponse("nonce", err)
 	}
-	
+
 	blockNumber := uint64(args[2].Float())
 	primeTerminusNumber := uint64(args[3].Float())
 	expectedMixHashHex := args[4].String()
+	if _, err := hexutil.DecodeFixed(expectedMixHashHex, 32); err != nil {
+		return errorResponse("mixHash", err)
+	}
+	expectedMixHash := expectedMixHashHex[2:]
 
 	// Parse difficulty - can be number or hex string
 	var difficulty *big.Int
 	if args[5].Type() == js.TypeNumber {
 		difficulty = big.NewInt(int64(args[5].Float()))
 	} else {
-		difficultyStr := args[5].String()
-		if len(difficultyStr) > 2 && difficultyStr[:2] == "0x" {
-			difficultyStr = difficultyStr[2:]
+		difficulty, err = hexutil.DecodeBig(args[5].String())
+		if err != nil {
+			return errorResponse("difficulty", err)
 		}
-		difficulty = new(big.Int)
-		difficulty.SetString(difficultyStr, 16)
 	}
-
-	// Remove 0x prefix if present
-	if len(headerHashHex) > 2 && headerHashHex[:2] == "0x" {
-		headerHashHex = headerHashHex[2:]
-	}
-	if len(expectedMixHashHex) > 2 && expectedMixHashHex[:2] == "0x" {
-		expectedMixHashHex = expectedMixHashHex[2:]
-	}
-
-	// Decode hex strings
-	headerHash, err := hex.DecodeString(headerHashHex)
-	if err != nil || len(headerHash) != 32 {
-		return map[string]interface{}{
-			"error": "Invalid header hash - must be 32 bytes hex",
-		}
-	}
-
-	expectedMixHash, err := hex.DecodeString(expectedMixHashHex)
-	if err != nil || len(expectedMixHash) != 32 {
-		return map[string]interface{}{
-			"error": "Invalid mix hash - must be 32 bytes hex",
-		}
+	if difficulty.Sign() <= 0 {
+		return errorResponse("difficulty", fmt.Errorf("difficulty must be positive"))
 	}
 
 	// Compute ProgPoW
@@ -212,7 +213,7 @@ func verifyProgPoW(this js.Value, args []js.Value) interface{} {
 	nonceHex := fmt.Sprintf("0x%016x", nonce)
 	result := computeProgPoW(this, []js.Value{
 		js.ValueOf(headerHashHex),
-		js.ValueOf(nonceHex),  // Pass as hex string to avoid precision loss
+		js.ValueOf(nonceHex), // Pass as hex string to avoid precision loss
 		js.ValueOf(blockNumber),
 		js.ValueOf(primeTerminusNumber),
 	})
@@ -230,7 +231,7 @@ func verifyProgPoW(this js.Value, args []js.Value) interface{} {
 	computedPowHash := resultMap["powHash"].(string)
 
 	// Verify mix hash matches
-	mixHashValid := computedMixHash == expectedMixHashHex
+	mixHashValid := computedMixHash == expectedMixHash
 
 	// Calculate target from difficulty
 	// target = 2^256 / difficulty
@@ -249,7 +250,7 @@ func verifyProgPoW(this js.Value, args []js.Value) interface{} {
 		"mixHashValid":    mixHashValid,
 		"powValid":        powValid,
 		"computedMixHash": computedMixHash,
-		"expectedMixHash": expectedMixHashHex,
+		"expectedMixHash": expectedMixHash,
 		"powHash":         computedPowHash,
 		"target":          hex.EncodeToString(target.Bytes()),
 		"difficulty":      difficulty.String(),
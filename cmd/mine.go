@@ -0,0 +1,154 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"syscall/js"
+
+	"github.com/dominant-strategies/progpow-wasm/epochcache"
+	"github.com/dominant-strategies/progpow-wasm/hexutil"
+	"github.com/dominant-strategies/progpow-wasm/progpow"
+)
+
+// defaultProgressInterval is how many nonces findProgPoWNonce tries between
+// onProgress callbacks when the caller doesn't specify one.
+const defaultProgressInterval = 100000
+
+// findProgPoWNonce searches a nonce range for one whose ProgPoW hash meets
+// difficulty, sharing the epoch cache so the light cache/cDAG for the
+// search's epoch is generated only once.
+// Args:
+//
+//	header: {headerHash (hex), primeTerminusNumber (number)}
+//	difficulty: number or hex string
+//	nonceRange: {start (hex or number), count (hex or number), stride? (hex or number),
+//	            signal? ({aborted: bool}), onProgress? (fn(hashesTried, elapsedMs)),
+//	            progressInterval? (hex or number)}
+//
+// Returns: {found, nonce, mixHash, powHash, hashesTried, hashrate}
+func findProgPoWNonce(this js.Value, args []js.Value) interface{} {
+	if len(args) < 3 {
+		return map[string]interface{}{
+			"error": "Expected: header, difficulty, nonceRange",
+		}
+	}
+
+	header := args[0]
+	headerHash, err := hexutil.DecodeFixed(header.Get("headerHash").String(), 32)
+	if err != nil {
+		return errorResponse("headerHash", err)
+	}
+	primeTerminusNumber := uint64(header.Get("primeTerminusNumber").Float())
+
+	var difficulty *big.Int
+	if args[1].Type() == js.TypeNumber {
+		difficulty = big.NewInt(int64(args[1].Float()))
+	} else {
+		difficulty, err = hexutil.DecodeBig(args[1].String())
+		if err != nil {
+			return errorResponse("difficulty", err)
+		}
+	}
+	if difficulty.Sign() <= 0 {
+		return errorResponse("difficulty", fmt.Errorf("difficulty must be positive"))
+	}
+	two256 := new(big.Int).Lsh(big.NewInt(1), 256)
+	target := new(big.Int).Div(two256, difficulty)
+
+	nonceRange := args[2]
+	start, err := parseNonceArg(nonceRange.Get("start"))
+	if err != nil {
+		return errorResponse("start", err)
+	}
+	count, err := parseNonceArg(nonceRange.Get("count"))
+	if err != nil {
+		return errorResponse("count", err)
+	}
+	stride := uint64(1)
+	if s := nonceRange.Get("stride"); s.Type() != js.TypeUndefined {
+		v, err := parseNonceArg(s)
+		if err != nil {
+			return errorResponse("stride", err)
+		}
+		if v > 0 {
+			stride = v
+		}
+	}
+	progressInterval := uint64(defaultProgressInterval)
+	if pi := nonceRange.Get("progressInterval"); pi.Type() != js.TypeUndefined {
+		v, err := parseNonceArg(pi)
+		if err != nil {
+			return errorResponse("progressInterval", err)
+		}
+		if v > 0 {
+			progressInterval = v
+		}
+	}
+	signal := nonceRange.Get("signal")
+	onProgress := nonceRange.Get("onProgress")
+
+	epoch := primeTerminusNumber / C_epochLength
+	entry, err := epochCache.Get(epoch, func() (*epochcache.Entry, error) {
+		return generateEpochEntry(epoch)
+	})
+	if err != nil {
+		return map[string]interface{}{
+			"error": fmt.Sprintf("Failed to generate epoch %d cache: %v", epoch, err),
+		}
+	}
+
+	now := func() float64 { return js.Global().Get("Date").Call("now").Float() }
+	startTime := now()
+
+	var (
+		hashesTried uint64
+		found       bool
+		foundNonce  uint64
+		foundMix    []byte
+		foundPow    []byte
+	)
+
+	for i := uint64(0); i < count; i++ {
+		if signal.Type() != js.TypeUndefined && signal.Get("aborted").Truthy() {
+			break
+		}
+
+		nonce := start + i*stride
+		mixHash, powHash := progpow.ProgpowLight(entry.DatasetSize, entry.Cache, headerHash, nonce, primeTerminusNumber, entry.CDag)
+		hashesTried++
+
+		if new(big.Int).SetBytes(powHash).Cmp(target) <= 0 {
+			found = true
+			foundNonce = nonce
+			foundMix = mixHash
+			foundPow = powHash
+			break
+		}
+
+		if onProgress.Type() == js.TypeFunction && hashesTried%progressInterval == 0 {
+			onProgress.Invoke(js.ValueOf(hashesTried), js.ValueOf(now()-startTime))
+		}
+	}
+
+	elapsedMs := now() - startTime
+	hashrate := float64(0)
+	if elapsedMs > 0 {
+		hashrate = float64(hashesTried) / (elapsedMs / 1000)
+	}
+
+	result := map[string]interface{}{
+		"found":       found,
+		"hashesTried": hashesTried,
+		"hashrate":    hashrate,
+	}
+	if found {
+		result["nonce"] = fmt.Sprintf("0x%016x", foundNonce)
+		result["mixHash"] = hex.EncodeToString(foundMix)
+		result["powHash"] = hex.EncodeToString(foundPow)
+	}
+	return result
+}
@@ -0,0 +1,266 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"syscall/js"
+
+	"github.com/dominant-strategies/progpow-wasm/hexutil"
+)
+
+const (
+	hashLength    = 32 // bytes in HeaderHash/ParentHash/TxHash/MixHash
+	addressLength = 20 // bytes in PrimaryCoinbase
+)
+
+// workObjectHeaderFieldNames lists every JS field encodeWorkObjectHeader and
+// computeWorkObjectSealHash understand. Any other own-property on the input
+// object is rejected so typos don't silently disappear.
+var workObjectHeaderFieldNames = map[string]bool{
+	"headerHash": true, "parentHash": true, "number": true, "difficulty": true,
+	"txHash": true, "primeTerminusNumber": true, "location": true, "lock": true,
+	"primaryCoinbase": true, "time": true, "data": true, "mixHash": true, "nonce": true,
+}
+
+// workObjectHeaderFields holds every decoded field of a WorkObjectHeader,
+// including MixHash and Nonce, which the seal hash omits.
+type workObjectHeaderFields struct {
+	headerHash          *ProtoHash
+	parentHash          *ProtoHash
+	number              []byte
+	difficulty          []byte
+	txHash              *ProtoHash
+	primeTerminusNumber []byte
+	location            *ProtoLocation
+	lock                uint32
+	primaryCoinbase     *ProtoAddress
+	time                uint64
+	data                []byte
+	mixHash             *ProtoHash
+	nonce               []byte
+}
+
+// rejectUnknownFields returns an error naming the first own-property of obj
+// that isn't a recognized WorkObjectHeader field.
+func rejectUnknownFields(obj js.Value) error {
+	keys := js.Global().Get("Object").Call("keys", obj)
+	for i := 0; i < keys.Length(); i++ {
+		key := keys.Index(i).String()
+		if !workObjectHeaderFieldNames[key] {
+			return fmt.Errorf("unknown field %q", key)
+		}
+	}
+	return nil
+}
+
+// extractWorkObjectHeaderFields decodes every WorkObjectHeader field from a
+// JS object. headerHash, parentHash and txHash are required and must be
+// exactly hashLength bytes; primaryCoinbase, if present and not the empty
+// "0x", must be exactly addressLength bytes. number, difficulty,
+// primeTerminusNumber and nonce are decoded as canonical hex quantities
+// (leading zeros are rejected) so they round-trip with
+// decodeWorkObjectHeader's quantityHex encoding.
+func extractWorkObjectHeaderFields(headerObj js.Value) (*workObjectHeaderFields, error) {
+	if err := rejectUnknownFields(headerObj); err != nil {
+		return nil, err
+	}
+
+	extractHash := func(fieldName string, required bool) (*ProtoHash, error) {
+		if headerObj.Get(fieldName).Type() == js.TypeUndefined {
+			if required {
+				return nil, hexutil.WrapField(fieldName, fmt.Errorf("required field is missing"))
+			}
+			return &ProtoHash{Value: []byte{}}, nil
+		}
+		b, err := hexutil.DecodeFixed(headerObj.Get(fieldName).String(), hashLength)
+		if err != nil {
+			return nil, hexutil.WrapField(fieldName, err)
+		}
+		return &ProtoHash{Value: b}, nil
+	}
+
+	extractBytes := func(fieldName string) ([]byte, error) {
+		if headerObj.Get(fieldName).Type() == js.TypeUndefined {
+			return nil, nil
+		}
+		b, err := hexutil.Decode(headerObj.Get(fieldName).String())
+		if err != nil {
+			return nil, hexutil.WrapField(fieldName, err)
+		}
+		return b, nil
+	}
+
+	// extractQuantityBytes decodes fieldName as a 0x-prefixed hex quantity
+	// (as emitted by quantityHex: no leading zeros, "0x0" for zero/absent)
+	// into its canonical big-endian bytes. hexutil.DecodeBig rejects
+	// non-minimal hex (e.g. "0x0001") outright, so only quantityHex's own
+	// canonical output round-trips through this function.
+	extractQuantityBytes := func(fieldName string) ([]byte, error) {
+		if headerObj.Get(fieldName).Type() == js.TypeUndefined {
+			return nil, nil
+		}
+		b, err := hexutil.DecodeBig(headerObj.Get(fieldName).String())
+		if err != nil {
+			return nil, hexutil.WrapField(fieldName, err)
+		}
+		return b.Bytes(), nil
+	}
+
+	extractAddress := func(fieldName string) (*ProtoAddress, error) {
+		if headerObj.Get(fieldName).Type() == js.TypeUndefined {
+			return &ProtoAddress{Value: []byte{}}, nil
+		}
+		b, err := hexutil.Decode(headerObj.Get(fieldName).String())
+		if err != nil {
+			return nil, hexutil.WrapField(fieldName, err)
+		}
+		if len(b) == 0 {
+			// Empty-but-present ("0x") is how decodeWorkObjectHeader emits
+			// an absent address; treat it the same as undefined.
+			return &ProtoAddress{Value: []byte{}}, nil
+		}
+		if len(b) != addressLength {
+			return nil, hexutil.WrapField(fieldName, fmt.Errorf("address must be %d bytes, got %d", addressLength, len(b)))
+		}
+		return &ProtoAddress{Value: b}, nil
+	}
+
+	extractUint32 := func(fieldName string) (uint32, error) {
+		if headerObj.Get(fieldName).Type() == js.TypeUndefined {
+			return 0, nil
+		}
+		value := headerObj.Get(fieldName)
+		if value.Type() == js.TypeString {
+			v, err := hexutil.DecodeUint64(value.String())
+			if err != nil {
+				return 0, hexutil.WrapField(fieldName, err)
+			}
+			return uint32(v), nil
+		}
+		return uint32(value.Int()), nil
+	}
+
+	extractUint64 := func(fieldName string) (uint64, error) {
+		if headerObj.Get(fieldName).Type() == js.TypeUndefined {
+			return 0, nil
+		}
+		value := headerObj.Get(fieldName)
+		if value.Type() == js.TypeString {
+			v, err := hexutil.DecodeUint64(value.String())
+			if err != nil {
+				return 0, hexutil.WrapField(fieldName, err)
+			}
+			return v, nil
+		}
+		return uint64(value.Float()), nil
+	}
+
+	fields := &workObjectHeaderFields{}
+
+	var err error
+	if fields.lock, err = extractUint32("lock"); err != nil {
+		return nil, err
+	}
+	if fields.time, err = extractUint64("time"); err != nil {
+		return nil, err
+	}
+	if fields.number, err = extractQuantityBytes("number"); err != nil {
+		return nil, err
+	}
+	if fields.headerHash, err = extractHash("headerHash", true); err != nil {
+		return nil, err
+	}
+	if fields.parentHash, err = extractHash("parentHash", true); err != nil {
+		return nil, err
+	}
+	if fields.difficulty, err = extractQuantityBytes("difficulty"); err != nil {
+		return nil, err
+	}
+	if fields.txHash, err = extractHash("txHash", true); err != nil {
+		return nil, err
+	}
+	if fields.primeTerminusNumber, err = extractQuantityBytes("primeTerminusNumber"); err != nil {
+		return nil, err
+	}
+	if fields.primaryCoinbase, err = extractAddress("primaryCoinbase"); err != nil {
+		return nil, err
+	}
+	if fields.data, err = extractBytes("data"); err != nil {
+		return nil, err
+	}
+	if fields.mixHash, err = extractHash("mixHash", false); err != nil {
+		return nil, err
+	}
+	if fields.nonce, err = extractQuantityBytes("nonce"); err != nil {
+		return nil, err
+	}
+	if headerObj.Get("location").Type() != js.TypeUndefined {
+		locBytes, err := extractBytes("location")
+		if err != nil {
+			return nil, err
+		}
+		fields.location = &ProtoLocation{Value: locBytes}
+	}
+
+	return fields, nil
+}
+
+// sealProto builds the ProtoWorkObjectHeader used for the seal hash, which
+// excludes MixHash and Nonce.
+func (f *workObjectHeaderFields) sealProto() *ProtoWorkObjectHeader {
+	lock := f.lock
+	time := f.time
+	return &ProtoWorkObjectHeader{
+		HeaderHash:          f.headerHash,
+		ParentHash:          f.parentHash,
+		Number:              f.number,
+		Difficulty:          f.difficulty,
+		TxHash:              f.txHash,
+		PrimeTerminusNumber: f.primeTerminusNumber,
+		Location:            f.location,
+		Lock:                &lock,
+		PrimaryCoinbase:     f.primaryCoinbase,
+		Time:                &time,
+		Data:                f.data,
+	}
+}
+
+// fullProto builds the ProtoWorkObjectHeader including MixHash and Nonce, for
+// the canonical headerHash.
+func (f *workObjectHeaderFields) fullProto() *ProtoWorkObjectHeader {
+	p := f.sealProto()
+	p.MixHash = f.mixHash
+	p.Nonce = f.nonce
+	return p
+}
+
+// validateWorkObjectHeader enforces the same invariants on a decoded proto
+// that extractWorkObjectHeaderFields enforces on JS input: HeaderHash,
+// ParentHash and TxHash must be present and exactly hashLength bytes, and
+// PrimaryCoinbase, if present and non-empty, must be exactly addressLength
+// bytes.
+func validateWorkObjectHeader(h *ProtoWorkObjectHeader) error {
+	if h.HeaderHash == nil || len(h.HeaderHash.Value) != hashLength {
+		return hexutil.WrapField("headerHash", fmt.Errorf("required field must be %d bytes", hashLength))
+	}
+	if h.ParentHash == nil || len(h.ParentHash.Value) != hashLength {
+		return hexutil.WrapField("parentHash", fmt.Errorf("required field must be %d bytes", hashLength))
+	}
+	if h.TxHash == nil || len(h.TxHash.Value) != hashLength {
+		return hexutil.WrapField("txHash", fmt.Errorf("required field must be %d bytes", hashLength))
+	}
+	if h.PrimaryCoinbase != nil && len(h.PrimaryCoinbase.Value) != 0 && len(h.PrimaryCoinbase.Value) != addressLength {
+		return hexutil.WrapField("primaryCoinbase", fmt.Errorf("address must be %d bytes, got %d", addressLength, len(h.PrimaryCoinbase.Value)))
+	}
+	return nil
+}
+
+// quantityHex converts a big-endian byte slice quantity (as stored in the
+// proto) into a canonical 0x-prefixed hex quantity: no leading zeros, "0x0"
+// for zero/absent.
+func quantityHex(b []byte) string {
+	return hexutil.EncodeBig(new(big.Int).SetBytes(b))
+}
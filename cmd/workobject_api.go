@@ -0,0 +1,114 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/dominant-strategies/progpow-wasm/hexutil"
+	"google.golang.org/protobuf/proto"
+	"lukechampine.com/blake3"
+)
+
+// encodeWorkObjectHeader marshals a full WorkObjectHeader (including
+// MixHash and Nonce) to protobuf and returns both the seal hash (over the
+// header with MixHash/Nonce excluded) and the canonical header hash (Blake3
+// of the full proto).
+// Args: header (object, see extractWorkObjectHeaderFields for the field set)
+// Returns: {proto: hex, sealHash: hex, headerHash: hex}
+func encodeWorkObjectHeader(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return map[string]interface{}{"error": "Missing header object"}
+	}
+
+	fields, err := extractWorkObjectHeaderFields(args[0])
+	if err != nil {
+		return errorResponseFromErr(err)
+	}
+
+	sealData, err := proto.Marshal(fields.sealProto())
+	if err != nil {
+		return map[string]interface{}{
+			"error": fmt.Sprintf("Failed to marshal seal protobuf: %v", err),
+		}
+	}
+	sealHash := blake3.Sum256(sealData)
+
+	fullData, err := proto.Marshal(fields.fullProto())
+	if err != nil {
+		return map[string]interface{}{
+			"error": fmt.Sprintf("Failed to marshal protobuf: %v", err),
+		}
+	}
+	headerHash := blake3.Sum256(fullData)
+
+	return map[string]interface{}{
+		"proto":      hexutil.Encode(fullData),
+		"sealHash":   hexutil.Encode(sealHash[:]),
+		"headerHash": hexutil.Encode(headerHash[:]),
+	}
+}
+
+// decodeWorkObjectHeader unmarshals a protobuf-encoded WorkObjectHeader and
+// returns every field as 0x-prefixed hex. number, difficulty,
+// primeTerminusNumber, nonce, lock and time are canonical quantities (no
+// leading zeros, "0x0" for zero), matching Ethereum RPC conventions; hashes,
+// primaryCoinbase, location and data keep every byte.
+// Args: protoHex (hex)
+// Returns: the header object, shaped like encodeWorkObjectHeader's input
+func decodeWorkObjectHeader(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return map[string]interface{}{"error": "Missing proto hex"}
+	}
+
+	protoBytes, err := hexutil.Decode(args[0].String())
+	if err != nil {
+		return errorResponse("proto", err)
+	}
+
+	var header ProtoWorkObjectHeader
+	if err := proto.Unmarshal(protoBytes, &header); err != nil {
+		return map[string]interface{}{
+			"error": fmt.Sprintf("Failed to unmarshal protobuf: %v", err),
+		}
+	}
+
+	if err := validateWorkObjectHeader(&header); err != nil {
+		return errorResponseFromErr(err)
+	}
+
+	var lock uint32
+	if header.Lock != nil {
+		lock = *header.Lock
+	}
+	var t uint64
+	if header.Time != nil {
+		t = *header.Time
+	}
+
+	result := map[string]interface{}{
+		"headerHash":          hexutil.Encode(header.HeaderHash.Value),
+		"parentHash":          hexutil.Encode(header.ParentHash.Value),
+		"txHash":              hexutil.Encode(header.TxHash.Value),
+		"number":              quantityHex(header.Number),
+		"difficulty":          quantityHex(header.Difficulty),
+		"primeTerminusNumber": quantityHex(header.PrimeTerminusNumber),
+		"nonce":               quantityHex(header.Nonce),
+		"lock":                hexutil.EncodeUint64(uint64(lock)),
+		"time":                hexutil.EncodeUint64(t),
+		"data":                hexutil.Encode(header.Data),
+	}
+	if header.Location != nil {
+		result["location"] = hexutil.Encode(header.Location.Value)
+	}
+	if header.PrimaryCoinbase != nil {
+		result["primaryCoinbase"] = hexutil.Encode(header.PrimaryCoinbase.Value)
+	}
+	if header.MixHash != nil && len(header.MixHash.Value) > 0 {
+		result["mixHash"] = hexutil.Encode(header.MixHash.Value)
+	}
+
+	return result
+}
@@ -0,0 +1,128 @@
+// Package epochcache memoizes the per-epoch ProgPoW light cache and cDAG so
+// that repeated verifications against the same epoch don't pay the
+// GenerateCache/GenerateCDag cost on every call. Generation is coalesced
+// across concurrent callers and old epochs are evicted on an LRU basis.
+package epochcache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Entry holds everything computeProgPoW needs for a given epoch.
+type Entry struct {
+	Epoch       uint64
+	Cache       []uint32
+	CDag        []uint32
+	DatasetSize uint64
+	Seed        []byte
+}
+
+// GenerateFunc produces the Entry for an epoch. While the epoch stays cached
+// it is invoked only once, even under concurrent Get calls, courtesy of
+// sync.Once; an evicted epoch is generated again on its next Get.
+type GenerateFunc func() (*Entry, error)
+
+// slot coalesces concurrent generation for a single epoch.
+type slot struct {
+	once  sync.Once
+	entry *Entry
+	err   error
+}
+
+// Manager is an LRU cache of Entry values keyed by epoch number.
+type Manager struct {
+	mu        sync.Mutex
+	maxEpochs int
+	slots     map[uint64]*slot
+	order     *list.List // front = most recently used, back = least recently used
+	elems     map[uint64]*list.Element
+}
+
+// NewManager creates a Manager that keeps at most maxEpochs entries alive at
+// once. maxEpochs is clamped to at least 1.
+func NewManager(maxEpochs int) *Manager {
+	if maxEpochs < 1 {
+		maxEpochs = 1
+	}
+	return &Manager{
+		maxEpochs: maxEpochs,
+		slots:     make(map[uint64]*slot),
+		order:     list.New(),
+		elems:     make(map[uint64]*list.Element),
+	}
+}
+
+// SetMaxEpochs changes the LRU capacity, evicting immediately if the new
+// limit is smaller than the current population.
+func (m *Manager) SetMaxEpochs(maxEpochs int) {
+	if maxEpochs < 1 {
+		maxEpochs = 1
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxEpochs = maxEpochs
+	m.evictLocked()
+}
+
+// Get returns the cached Entry for epoch, generating it via generate if it
+// isn't already cached. Concurrent calls for the same epoch share a single
+// generation.
+func (m *Manager) Get(epoch uint64, generate GenerateFunc) (*Entry, error) {
+	m.mu.Lock()
+	s, ok := m.slots[epoch]
+	if !ok {
+		s = &slot{}
+		m.slots[epoch] = s
+	}
+	m.touchLocked(epoch)
+	m.mu.Unlock()
+
+	s.once.Do(func() {
+		s.entry, s.err = generate()
+	})
+	return s.entry, s.err
+}
+
+// touchLocked records epoch as most recently used and evicts the
+// least-recently-used entries once the manager is over capacity. Callers
+// must hold m.mu.
+func (m *Manager) touchLocked(epoch uint64) {
+	if elem, ok := m.elems[epoch]; ok {
+		m.order.MoveToFront(elem)
+	} else {
+		m.elems[epoch] = m.order.PushFront(epoch)
+	}
+	m.evictLocked()
+}
+
+func (m *Manager) evictLocked() {
+	for m.order.Len() > m.maxEpochs {
+		back := m.order.Back()
+		if back == nil {
+			return
+		}
+		epoch := back.Value.(uint64)
+		m.order.Remove(back)
+		delete(m.elems, epoch)
+		delete(m.slots, epoch)
+	}
+}
+
+// Stats describes the current cache population, most recently used first.
+type Stats struct {
+	MaxEpochs int
+	Epochs    []uint64
+}
+
+// Stats reports the manager's configured capacity and the epochs currently
+// cached (or in flight), most recently used first.
+func (m *Manager) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	epochs := make([]uint64, 0, m.order.Len())
+	for e := m.order.Front(); e != nil; e = e.Next() {
+		epochs = append(epochs, e.Value.(uint64))
+	}
+	return Stats{MaxEpochs: m.maxEpochs, Epochs: epochs}
+}
@@ -0,0 +1,191 @@
+// Package hexutil implements strict 0x-prefixed hex encoding and decoding,
+// following the conventions used across the go-ethereum codebase. Unlike
+// encoding/hex, it requires callers to write out the "0x" prefix, rejects
+// odd-length input instead of silently padding it, and forbids leading
+// zeros on decoded quantities.
+package hexutil
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Errors returned when decoding malformed hex input. These mirror the
+// sentinel errors go-ethereum's hexutil package exposes so that callers can
+// match on them with errors.Is.
+var (
+	ErrEmptyString   = &decError{"empty hex string"}
+	ErrMissingPrefix = &decError{"hex string without 0x prefix"}
+	ErrOddLength     = &decError{"hex string of odd length"}
+	ErrSyntax        = &decError{"invalid hex string"}
+	ErrEmptyNumber   = &decError{"hex string \"0x\""}
+	ErrLeadingZero   = &decError{"hex number with leading zero digits"}
+	ErrUint64Range   = &decError{"hex number > 64 bits"}
+)
+
+type decError struct{ msg string }
+
+func (err *decError) Error() string { return err.msg }
+
+// FieldError wraps a decoding error with the name of the JS-supplied field
+// that failed to decode, so callers crossing the WASM boundary can report
+// actionable diagnostics instead of swallowing the failure.
+type FieldError struct {
+	Field  string
+	Reason error
+}
+
+func (e *FieldError) Error() string { return fmt.Sprintf("%s: %v", e.Field, e.Reason) }
+
+func (e *FieldError) Unwrap() error { return e.Reason }
+
+// WrapField annotates err with the field name it came from. It returns nil
+// if err is nil.
+func WrapField(field string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &FieldError{Field: field, Reason: err}
+}
+
+func has0xPrefix(input string) bool {
+	return len(input) >= 2 && input[0] == '0' && (input[1] == 'x' || input[1] == 'X')
+}
+
+// Decode decodes a 0x-prefixed hex string into a byte slice. The input must
+// carry the "0x" prefix and must have an even number of hex digits.
+func Decode(input string) ([]byte, error) {
+	if len(input) == 0 {
+		return nil, ErrEmptyString
+	}
+	if !has0xPrefix(input) {
+		return nil, ErrMissingPrefix
+	}
+	b, err := hex.DecodeString(input[2:])
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return b, nil
+}
+
+// MustDecode decodes a 0x-prefixed hex string. It panics if the input is
+// malformed and is meant for use in tests or on values that are already
+// known to be valid.
+func MustDecode(input string) []byte {
+	dec, err := Decode(input)
+	if err != nil {
+		panic(err)
+	}
+	return dec
+}
+
+// DecodeFixed decodes a 0x-prefixed hex string and requires the result to be
+// exactly n bytes long.
+func DecodeFixed(input string, n int) ([]byte, error) {
+	b, err := Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != n {
+		return nil, fmt.Errorf("hex string has length %d, want %d bytes", len(b), n)
+	}
+	return b, nil
+}
+
+// checkNumber validates the 0x prefix of a hex-encoded quantity and strips
+// it, rejecting the empty-after-prefix case and any leading zero digit.
+func checkNumber(input string) (raw string, err error) {
+	if len(input) == 0 {
+		return "", ErrEmptyString
+	}
+	if !has0xPrefix(input) {
+		return "", ErrMissingPrefix
+	}
+	input = input[2:]
+	if len(input) == 0 {
+		return "", ErrEmptyNumber
+	}
+	if len(input) > 1 && input[0] == '0' {
+		return "", ErrLeadingZero
+	}
+	return input, nil
+}
+
+// DecodeUint64 decodes a 0x-prefixed hex quantity into a uint64. Leading
+// zeros are rejected (except for the literal "0x0") and values that
+// overflow 64 bits return ErrUint64Range.
+func DecodeUint64(input string) (uint64, error) {
+	raw, err := checkNumber(input)
+	if err != nil {
+		return 0, err
+	}
+	dec, err := strconv.ParseUint(raw, 16, 64)
+	if err != nil {
+		return 0, mapError(err)
+	}
+	return dec, nil
+}
+
+// DecodeBig decodes a 0x-prefixed hex quantity into a big.Int. Leading
+// zeros are rejected (except for the literal "0x0").
+func DecodeBig(input string) (*big.Int, error) {
+	raw, err := checkNumber(input)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := new(big.Int).SetString(raw, 16)
+	if !ok {
+		return nil, ErrSyntax
+	}
+	return b, nil
+}
+
+// Encode hex-encodes b as a 0x-prefixed string, preserving every byte. Use
+// this for hashes, addresses and other fixed-layout values where leading
+// zero bytes are significant.
+func Encode(b []byte) string {
+	enc := make([]byte, len(b)*2+2)
+	copy(enc, "0x")
+	hex.Encode(enc[2:], b)
+	return string(enc)
+}
+
+// EncodeUint64 encodes i as a canonical 0x-prefixed hex quantity: no
+// leading zeros, and "0x0" for zero.
+func EncodeUint64(i uint64) string {
+	enc := make([]byte, 2, 10)
+	copy(enc, "0x")
+	return string(strconv.AppendUint(enc, i, 16))
+}
+
+// EncodeBig encodes i as a canonical 0x-prefixed hex quantity: no leading
+// zeros, and "0x0" for zero. A nil i encodes as "0x0".
+func EncodeBig(i *big.Int) string {
+	if i == nil || i.Sign() == 0 {
+		return "0x0"
+	}
+	return fmt.Sprintf("0x%x", i)
+}
+
+// mapError translates the low-level errors returned by encoding/hex and
+// strconv into the sentinel errors above.
+func mapError(err error) error {
+	switch e := err.(type) {
+	case hex.InvalidByteError:
+		return ErrSyntax
+	case *strconv.NumError:
+		switch e.Err {
+		case strconv.ErrRange:
+			return ErrUint64Range
+		case strconv.ErrSyntax:
+			return ErrSyntax
+		}
+		return e.Err
+	}
+	if err == hex.ErrLength {
+		return ErrOddLength
+	}
+	return err
+}